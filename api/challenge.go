@@ -0,0 +1,209 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dimo-network/trips-web-app-new/api/api/internal/config"
+	"github.com/dimo-network/trips-web-app-new/api/internal/sessionstore"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// challengeStore holds pending challenges, JSON-encoded, keyed by
+// challenge ID while the caller completes one or more authentication
+// factors. Entries expire on their own TTL so an abandoned challenge
+// never needs explicit cleanup.
+var challengeStore sessionstore.Store = sessionstore.NewMemoryStore(5 * time.Minute)
+
+// factorWeb3Signature is the only factor supported today; additional
+// factors (e.g. "totp") can be appended to PendingChallenge.Factors
+// without changing the generate/submit route shapes.
+const factorWeb3Signature = "web3_signature"
+
+// PendingChallenge is everything we need to remember between
+// HandleGenerateChallenge and HandleSubmitChallenge for a single challenge.
+type PendingChallenge struct {
+	Address       string
+	ExternalState string
+	Factors       []string
+	Fingerprint   string
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+}
+
+// fingerprintRequest hashes the caller's IP and User-Agent so the submit
+// step can be rejected if it doesn't originate from the same client that
+// started the challenge.
+func fingerprintRequest(c *fiber.Ctx) string {
+	sum := sha256.Sum256([]byte(c.IP() + "|" + c.Get(fiber.HeaderUserAgent)))
+	return hex.EncodeToString(sum[:])
+}
+
+func factorSupported(factors []string, factorID string) bool {
+	for _, f := range factors {
+		if f == factorID {
+			return true
+		}
+	}
+	return false
+}
+
+func HandleGenerateChallenge(c *fiber.Ctx, settings *config.Settings) error {
+	address := c.FormValue("address")
+
+	formData := url.Values{}
+	formData.Add("client_id", settings.ClientID)
+	formData.Add("domain", settings.Domain)
+	formData.Add("scope", settings.Scope)
+	formData.Add("response_type", settings.ResponseType)
+	formData.Add("address", address)
+
+	encodedFormData := formData.Encode()
+	reqURL := settings.AuthURL
+
+	req, err := http.NewRequestWithContext(c.UserContext(), "POST", reqURL, strings.NewReader(encodedFormData))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Failed to build request to external service")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := apiHTTPClient.Do(req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Failed to make request to external service")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error reading external response")
+	}
+
+	var apiResp ChallengeResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error processing response from external service")
+	}
+
+	if apiResp.State == "" || apiResp.Challenge == "" {
+		return c.Status(fiber.StatusInternalServerError).SendString("State or Challenge incomplete from external service")
+	}
+
+	challengeID := uuid.New().String()
+	factors := []string{factorWeb3Signature}
+	now := time.Now()
+	pending := PendingChallenge{
+		Address:       address,
+		ExternalState: apiResp.State,
+		Factors:       factors,
+		Fingerprint:   fingerprintRequest(c),
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(5 * time.Minute),
+	}
+	encoded, err := json.Marshal(pending)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error storing challenge")
+	}
+	if err := challengeStore.Set(challengeID, string(encoded)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error storing challenge")
+	}
+
+	return c.JSON(fiber.Map{
+		"challenge_id": challengeID,
+		"challenge":    apiResp.Challenge,
+		"factors":      factors,
+	})
+}
+
+func HandleSubmitChallenge(c *fiber.Ctx, settings *config.Settings) error {
+	challengeID := c.FormValue("challenge_id")
+	factorID := c.FormValue("factor_id")
+	secret := c.FormValue("secret")
+
+	raw, found := challengeStore.Get(challengeID)
+	if !found {
+		return c.Status(fiber.StatusUnauthorized).SendString("Challenge not found or expired")
+	}
+	var pending PendingChallenge
+	if err := json.Unmarshal([]byte(raw), &pending); err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error reading challenge")
+	}
+
+	if fingerprintRequest(c) != pending.Fingerprint {
+		log.Warn().Str("challenge_id", challengeID).Msg("Challenge fingerprint mismatch")
+		return c.Status(fiber.StatusUnauthorized).SendString("Challenge fingerprint mismatch")
+	}
+
+	if !factorSupported(pending.Factors, factorID) {
+		return c.Status(fiber.StatusUnauthorized).SendString("Unsupported factor")
+	}
+
+	log.Info().Msgf("State: %s, Factor: %s", pending.ExternalState, factorID)
+
+	formData := url.Values{}
+	formData.Add("client_id", settings.ClientID)
+	formData.Add("domain", settings.Domain)
+	formData.Add("grant_type", settings.GrantType)
+	formData.Add("state", pending.ExternalState)
+	formData.Add("signature", secret)
+
+	encodedFormData := formData.Encode()
+	reqURL := settings.SubmitChallengeURL
+
+	req, err := http.NewRequestWithContext(c.UserContext(), "POST", reqURL, strings.NewReader(encodedFormData))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Failed to build request to external service")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := apiHTTPClient.Do(req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Failed to make request to external service")
+	}
+	defer resp.Body.Close()
+
+	// Check the HTTP status code here
+	if resp.StatusCode >= 300 {
+		return c.Status(fiber.StatusInternalServerError).SendString(fmt.Sprintf("Received non-success status code: %d", resp.StatusCode))
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Failed to read response from external service")
+	}
+
+	var responseMap map[string]interface{}
+	if err := json.Unmarshal(respBody, &responseMap); err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error processing response")
+	}
+
+	token, exists := responseMap["access_token"].(string)
+	if !exists {
+		return c.Status(fiber.StatusInternalServerError).SendString("Token not found in response")
+	}
+
+	challengeStore.Delete(challengeID)
+
+	sessionID := uuid.New().String()
+	if err := cacheInstance.Set(sessionID, token); err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error starting session")
+	}
+
+	cookie := new(fiber.Cookie)
+	cookie.Name = "session_id"
+	cookie.Value = sessionID
+	cookie.Expires = time.Now().Add(2 * time.Hour)
+	cookie.HTTPOnly = true
+	cookie.Domain = "localhost"
+
+	c.Cookie(cookie)
+
+	return c.JSON(fiber.Map{"message": "Challenge accepted and session started!", "access_token": token})
+}
@@ -3,15 +3,18 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/dimo-network/trips-web-app/api/internal/config"
+	"github.com/dimo-network/trips-web-app-new/api/api/internal/config"
+	"github.com/dimo-network/trips-web-app-new/api/internal/tripexport"
+	"github.com/dimo-network/trips-web-app-new/api/trips/analytics"
 	"github.com/gofiber/fiber/v2"
-	geojson "github.com/paulmach/go.geojson"
+	"github.com/paulmach/orb"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"io"
 	"net/http"
 	"net/url"
 	"sort"
+	"time"
 )
 
 type Trip struct {
@@ -31,8 +34,9 @@ type TripsResponse struct {
 var tripIDToTokenIDMap = make(map[string]int64)
 
 type LocationData struct {
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 func queryTripsAPI(tokenID int64, settings *config.Settings, c *fiber.Ctx) ([]Trip, error) {
@@ -51,14 +55,13 @@ func queryTripsAPI(tokenID int64, settings *config.Settings, c *fiber.Ctx) ([]Tr
 
 	url := fmt.Sprintf("%s/vehicle/%d/trips", settings.TripsAPIBaseURL, tokenID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(c.UserContext(), "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", "Bearer "+token.(string))
+	req.Header.Set("Authorization", "Bearer "+token)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := apiHTTPClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -92,14 +95,13 @@ func queryDeviceDataHistory(tokenID int64, startTime string, endTime string, set
 
 	ddUrl := fmt.Sprintf("%s/vehicle/%d/history?startDate=%s&endDate=%s", settings.DeviceDataAPIBaseURL, tokenID, url.QueryEscape(startTime), url.QueryEscape(endTime))
 
-	req, err := http.NewRequest("GET", ddUrl, nil)
+	req, err := http.NewRequestWithContext(c.UserContext(), "GET", ddUrl, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", "Bearer "+token.(string))
+	req.Header.Set("Authorization", "Bearer "+token)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := apiHTTPClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -133,6 +135,10 @@ func queryDeviceDataHistory(tokenID int64, startTime string, endTime string, set
 	return locations, nil
 }
 
+// handleMapDataForTrip serves a single trip's route in whichever format
+// the caller negotiates (GeoJSON by default, or gpx/kml/csv via the
+// Accept header or a ?format= query param), applying the caller's
+// privacy zones and endpoint trimming along the way.
 func handleMapDataForTrip(c *fiber.Ctx, settings *config.Settings, tripID, startTime, endTime string) error {
 	tokenID, exists := tripIDToTokenIDMap[tripID]
 	if !exists {
@@ -141,23 +147,28 @@ func handleMapDataForTrip(c *fiber.Ctx, settings *config.Settings, tripID, start
 
 	log.Info().Msgf("HandleMapDataForTrip: TripID: %s, StartTime: %s, EndTime: %s, TokenID: %d", tripID, startTime, endTime, tokenID)
 
-	// Fetch historical data for the specific trip
 	locations, err := queryDeviceDataHistory(tokenID, startTime, endTime, settings, c)
-
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch historical data: " + err.Error()})
 	}
 
-	// Convert the historical data to GeoJSON
-	geoJSON := convertToGeoJSON(locations, tripID, startTime, endTime)
+	format := negotiateExportFormat(c)
+	encoder, ok := tripexport.EncoderFor(format)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).SendString("Unsupported export format")
+	}
 
-	geoJSONData, err := json.Marshal(geoJSON)
-	if err != nil {
-		log.Error().Msgf("Error with GeoJSON: %v", err)
-	} else {
-		log.Info().Msgf("GeoJSON data: %s", string(geoJSONData))
+	ethAddress, _ := c.Locals(EthereumAddressKey).(string)
+	segments, meta := buildExport(locations, tripID, startTime, endTime, ethAddress, settings)
+
+	c.Set(fiber.HeaderContentType, encoder.ContentType())
+	if format != "geojson" {
+		// The map view fetches GeoJSON inline; only the other formats are
+		// meant to be downloaded as files.
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.%s"`, tripID, encoder.Extension()))
 	}
-	return c.JSON(geoJSON)
+
+	return encoder.Encode(c.Response().BodyWriter(), meta, segments)
 }
 
 func extractLocationData(hits []interface{}) []LocationData {
@@ -169,34 +180,61 @@ func extractLocationData(hits []interface{}) []LocationData {
 			Latitude:  data["latitude"].(float64),
 			Longitude: data["longitude"].(float64),
 		}
+		if ts, ok := data["timestamp"].(string); ok {
+			if parsed, err := time.Parse(time.RFC3339, ts); err == nil {
+				locData.Timestamp = parsed
+			}
+		}
 		locations = append(locations, locData)
 	}
 	return locations
 }
 
-func convertToGeoJSON(locations []LocationData, tripID string, tripStart string, tripEnd string) *geojson.FeatureCollection {
-	coords := make([][]float64, 0, len(locations))
+// pointsOf extracts the raw coordinates from locations, in order.
+func pointsOf(locations []LocationData) []orb.Point {
+	points := make([]orb.Point, len(locations))
+	for i, loc := range locations {
+		points[i] = orb.Point{loc.Longitude, loc.Latitude}
+	}
+	return points
+}
 
-	for _, loc := range locations {
-		// Append each location as a coordinate pair in the coords slice
-		coords = append(coords, []float64{loc.Longitude, loc.Latitude})
+// tripMetrics computes distance/speed/stop metrics from only the
+// locations that survive privacy-zone and endpoint-trim redaction, so
+// BoundingBox and Stops never republish coordinates the redaction was
+// meant to hide. indexSegments comes from privacy.Store.RedactIndices.
+func tripMetrics(locations []LocationData, indexSegments [][]int) analytics.Metrics {
+	perSegment := make([]analytics.Metrics, len(indexSegments))
+	for i, idxs := range indexSegments {
+		fixes := make([]analytics.Fix, len(idxs))
+		for j, idx := range idxs {
+			loc := locations[idx]
+			fixes[j] = analytics.Fix{Point: orb.Point{loc.Longitude, loc.Latitude}, Timestamp: loc.Timestamp}
+		}
+		perSegment[i] = analytics.Compute(fixes, analytics.DefaultOptions())
 	}
+	return analytics.MergeMetrics(perSegment)
+}
 
-	feature := geojson.NewLineStringFeature(coords)
+// HandleTripStats computes and returns distance, speed, and stop metrics
+// for a single trip, redacted the same way as the trip's map data.
+func HandleTripStats(c *fiber.Ctx, settings *config.Settings) error {
+	tripID := c.Params("tripId")
+	startTime := c.Query("startTime")
+	endTime := c.Query("endTime")
 
-	feature.Properties = map[string]interface{}{
-		"type":         "LineString",
-		"trip_id":      tripID,
-		"trip_start":   tripStart,
-		"trip_end":     tripEnd,
-		"privacy_zone": 1,
-		"color":        "black",
-		"point-color":  "black",
+	tokenID, exists := tripIDToTokenIDMap[tripID]
+	if !exists {
+		return c.Status(fiber.StatusNotFound).SendString("Trip not found")
+	}
+
+	locations, err := queryDeviceDataHistory(tokenID, startTime, endTime, settings, c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch historical data: " + err.Error()})
 	}
 
-	// Create a feature collection and add the LineString feature to it
-	fc := geojson.NewFeatureCollection()
-	fc.AddFeature(feature)
+	ethAddress, _ := c.Locals(EthereumAddressKey).(string)
+	indexSegments, _ := privacyZoneStore.RedactIndices(ethAddress, pointsOf(locations), settings.PrivacyTrimRadiusMeters)
 
-	return fc
+	return c.JSON(tripMetrics(locations, indexSegments))
 }
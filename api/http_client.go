@@ -0,0 +1,8 @@
+package main
+
+import "github.com/dimo-network/trips-web-app-new/api/internal/httpclient"
+
+// apiHTTPClient is the shared outbound client for every call to an
+// upstream service (auth, identity-api, trips-api, device-data-api). It
+// is configured from settings once in main().
+var apiHTTPClient *httpclient.Client
@@ -0,0 +1,37 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker is a simple per-host circuit breaker: once a host accumulates
+// failureThreshold consecutive failures, it's considered open and calls
+// are rejected without hitting the network until cooldown elapses.
+type breaker struct {
+	mu               sync.Mutex
+	consecutiveFails int
+	openUntil        time.Time
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Now().After(b.openUntil)
+}
+
+func (b *breaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFails = 0
+	b.openUntil = time.Time{}
+}
+
+func (b *breaker) recordFailure(failureThreshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFails++
+	if b.consecutiveFails >= failureThreshold {
+		b.openUntil = time.Now().Add(cooldown)
+	}
+}
@@ -0,0 +1,151 @@
+// Package httpclient provides an outbound HTTP client with per-request
+// deadlines, retry-with-backoff, and a per-host circuit breaker, so a
+// hung upstream can't pin a Fiber worker forever. Every call goes
+// through Do(req); Do takes no separate context argument and uses the
+// one carried by req, so callers should build req from c.UserContext()
+// so a client disconnect cancels the upstream fetch.
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dimo-network/trips-web-app-new/api/api/internal/config"
+)
+
+// Client wraps http.Client with configurable connect/read/total
+// deadlines, retry-with-backoff, and a circuit breaker keyed per
+// upstream host.
+type Client struct {
+	http *http.Client
+
+	readTimeout  time.Duration
+	totalTimeout time.Duration
+
+	maxRetries      int
+	retryBackoff    time.Duration
+	breakerFailures int
+	breakerCooldown time.Duration
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// New builds a Client from settings.
+func New(settings *config.Settings) *Client {
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{Timeout: settings.HTTPConnectTimeout}).DialContext,
+	}
+
+	return &Client{
+		http:            &http.Client{Transport: transport},
+		readTimeout:     settings.HTTPReadTimeout,
+		totalTimeout:    settings.HTTPTotalTimeout,
+		maxRetries:      settings.HTTPMaxRetries,
+		retryBackoff:    settings.HTTPRetryBackoff,
+		breakerFailures: settings.HTTPBreakerFailureThreshold,
+		breakerCooldown: settings.HTTPBreakerCooldown,
+		breakers:        make(map[string]*breaker),
+	}
+}
+
+func (c *Client) breakerFor(host string) *breaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.breakers[host]
+	if !ok {
+		b = &breaker{}
+		c.breakers[host] = b
+	}
+	return b
+}
+
+// Do executes req, bounding the whole attempt (including retries) by the
+// client's total timeout and each individual attempt by its read
+// timeout. req's context (set via req.WithContext, typically from
+// c.UserContext()) is honored too, so cancelling it — e.g. because the
+// caller disconnected — aborts the upstream fetch. Requests to a host
+// whose circuit breaker is open fail fast without touching the network.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	totalDeadline := NewDeadline()
+	totalDeadline.Reset(c.totalTimeout)
+	defer totalDeadline.Stop()
+
+	host := req.URL.Host
+	b := c.breakerFor(host)
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if !b.allow() {
+			return nil, fmt.Errorf("httpclient: circuit open for %s", host)
+		}
+
+		attemptTimeout := c.readTimeout
+		if c.totalTimeout > 0 {
+			if remaining := time.Until(start.Add(c.totalTimeout)); remaining < attemptTimeout {
+				attemptTimeout = remaining
+			}
+		}
+		attemptCtx, attemptCancel := context.WithTimeout(req.Context(), attemptTimeout)
+		attemptReq := req.Clone(attemptCtx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				attemptCancel()
+				return nil, fmt.Errorf("httpclient: rewinding request body for retry: %w", err)
+			}
+			attemptReq.Body = body
+		}
+
+		resp, err := c.http.Do(attemptReq)
+
+		if err == nil && resp.StatusCode < 500 {
+			b.recordSuccess()
+			// The attempt's context also governs reading the body, so it
+			// is only cancelled once the caller closes the body.
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: attemptCancel}
+			return resp, nil
+		}
+		attemptCancel()
+
+		if err == nil {
+			resp.Body.Close()
+			err = fmt.Errorf("httpclient: upstream %s returned %d", host, resp.StatusCode)
+		}
+		lastErr = err
+		b.recordFailure(c.breakerFailures, c.breakerCooldown)
+
+		if attempt == c.maxRetries {
+			break
+		}
+
+		select {
+		case <-totalDeadline.Done():
+			return nil, fmt.Errorf("httpclient: total deadline exceeded calling %s: %w", host, lastErr)
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(c.retryBackoff * time.Duration(1<<attempt)):
+		}
+	}
+
+	return nil, lastErr
+}
+
+// cancelOnClose cancels an attempt's context when its response body is
+// closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.cancel()
+	return err
+}
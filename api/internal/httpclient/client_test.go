@@ -0,0 +1,131 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dimo-network/trips-web-app-new/api/api/internal/config"
+)
+
+func testSettings() *config.Settings {
+	return &config.Settings{
+		HTTPConnectTimeout:          time.Second,
+		HTTPReadTimeout:             time.Second,
+		HTTPTotalTimeout:            5 * time.Second,
+		HTTPMaxRetries:              2,
+		HTTPRetryBackoff:            time.Millisecond,
+		HTTPBreakerFailureThreshold: 2,
+		HTTPBreakerCooldown:         time.Minute,
+	}
+}
+
+func TestDoRetriesAndResendsBody(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&attempts, 1)
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("attempt %d: reading body: %v", n, err)
+		}
+		if string(body) != "hello" {
+			t.Errorf("attempt %d: body = %q, want %q", n, body, "hello")
+		}
+		if n < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// Keep the breaker closed across the two failed attempts so the third
+	// one reaches the server.
+	settings := testSettings()
+	settings.HTTPBreakerFailureThreshold = 3
+	client := New(settings)
+
+	req, err := http.NewRequestWithContext(context.Background(), "POST", srv.URL, strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() = %v, want success", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+}
+
+func TestDoOpensCircuitAfterConsecutiveFailures(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	settings := testSettings()
+	settings.HTTPMaxRetries = 0
+	settings.HTTPBreakerFailureThreshold = 1
+	client := New(settings)
+
+	req, err := http.NewRequestWithContext(context.Background(), "GET", srv.URL, nil)
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("first call: want error from upstream 500")
+	}
+	hitsAfterFirst := atomic.LoadInt32(&hits)
+
+	req2, _ := http.NewRequestWithContext(context.Background(), "GET", srv.URL, nil)
+	_, err = client.Do(req2)
+	if err == nil || !strings.Contains(err.Error(), "circuit open") {
+		t.Fatalf("second call: err = %v, want circuit open error", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != hitsAfterFirst {
+		t.Errorf("hits after circuit opened = %d, want %d (no network call)", got, hitsAfterFirst)
+	}
+}
+
+func TestDoHonorsRequestContextCancellation(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer func() {
+		close(block)
+		srv.Close()
+	}()
+
+	client := New(testSettings())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("want error once the request context is cancelled")
+	}
+}
@@ -0,0 +1,62 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// Deadline is a resettable timer that closes a channel when it fires,
+// mirroring the deadlineTimer type the net package uses internally for
+// connection read/write deadlines. Reset can be called repeatedly to
+// push the deadline out (e.g. once per retry attempt) without leaking
+// the previous timer.
+type Deadline struct {
+	mu   sync.Mutex
+	t    *time.Timer
+	done chan struct{}
+}
+
+// NewDeadline returns a Deadline that hasn't fired yet and has no timer
+// running; call Reset to arm it.
+func NewDeadline() *Deadline {
+	return &Deadline{done: make(chan struct{})}
+}
+
+// Reset (re)arms the deadline to fire after d, replacing any previously
+// scheduled timer. A non-positive d leaves the deadline disarmed.
+func (d *Deadline) Reset(duration time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.t != nil {
+		d.t.Stop()
+	}
+	if duration <= 0 {
+		return
+	}
+
+	done := d.done
+	d.t = time.AfterFunc(duration, func() {
+		select {
+		case <-done:
+		default:
+			close(done)
+		}
+	})
+}
+
+// Stop disarms the deadline without firing it.
+func (d *Deadline) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.t != nil {
+		d.t.Stop()
+	}
+}
+
+// Done returns the channel that closes when the deadline fires.
+func (d *Deadline) Done() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.done
+}
@@ -0,0 +1,173 @@
+// Package privacy implements per-user privacy zones: circular areas
+// (home, work, or custom) that get redacted from a trip's published
+// route, plus the trim-the-endpoints redaction that applies to every
+// trip regardless of configured zones.
+package privacy
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geo"
+)
+
+// ZoneType distinguishes the common zone presets from user-defined ones.
+type ZoneType string
+
+const (
+	ZoneHome   ZoneType = "home"
+	ZoneWork   ZoneType = "work"
+	ZoneCustom ZoneType = "custom"
+)
+
+// Zone is a circular region a user wants redacted from their trips.
+type Zone struct {
+	ID           string   `json:"id"`
+	Type         ZoneType `json:"type"`
+	Latitude     float64  `json:"latitude"`
+	Longitude    float64  `json:"longitude"`
+	RadiusMeters float64  `json:"radius_meters"`
+}
+
+// ErrZoneNotFound is returned by Update and Delete when the zone ID
+// doesn't belong to the given address.
+var ErrZoneNotFound = errors.New("privacy zone not found")
+
+// Store holds each user's configured zones in memory, keyed by their
+// Ethereum address.
+type Store struct {
+	mu    sync.RWMutex
+	zones map[string][]Zone
+}
+
+// NewStore returns an empty zone store.
+func NewStore() *Store {
+	return &Store{zones: make(map[string][]Zone)}
+}
+
+// List returns a copy of address's configured zones.
+func (s *Store) List(address string) []Zone {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Zone(nil), s.zones[address]...)
+}
+
+// Create adds a new zone for address, assigning it an ID.
+func (s *Store) Create(address string, zone Zone) Zone {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	zone.ID = uuid.New().String()
+	s.zones[address] = append(s.zones[address], zone)
+	return zone
+}
+
+// Update replaces the zone matching zone.ID for address.
+func (s *Store) Update(address string, zone Zone) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, z := range s.zones[address] {
+		if z.ID == zone.ID {
+			s.zones[address][i] = zone
+			return nil
+		}
+	}
+	return ErrZoneNotFound
+}
+
+// Delete removes zoneID from address's zones.
+func (s *Store) Delete(address, zoneID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	zones := s.zones[address]
+	for i, z := range zones {
+		if z.ID == zoneID {
+			s.zones[address] = append(zones[:i], zones[i+1:]...)
+			return nil
+		}
+	}
+	return ErrZoneNotFound
+}
+
+// Redact trims the leading and trailing points of points that fall
+// within trimRadiusMeters of the trip's first/last fix, then drops any
+// remaining point that falls inside one of address's configured zones.
+// A zone hit in the middle of the trip splits the route, so the result
+// is zero or more line segments (zero segments, or a single segment,
+// serializes as a LineString; more than one as a MultiLineString). It
+// also returns the IDs of every zone that intersected the trip.
+func (s *Store) Redact(address string, points []orb.Point, trimRadiusMeters float64) ([][]orb.Point, []string) {
+	indexSegments, applied := s.RedactIndices(address, points, trimRadiusMeters)
+
+	segments := make([][]orb.Point, len(indexSegments))
+	for i, idxs := range indexSegments {
+		seg := make([]orb.Point, len(idxs))
+		for j, idx := range idxs {
+			seg[j] = points[idx]
+		}
+		segments[i] = seg
+	}
+
+	return segments, applied
+}
+
+// RedactIndices applies the same trimming and zone redaction as Redact,
+// but returns indices into points rather than copies of the points
+// themselves, so a caller holding richer per-point data (e.g. a
+// timestamp) can re-associate it with the surviving points.
+func (s *Store) RedactIndices(address string, points []orb.Point, trimRadiusMeters float64) ([][]int, []string) {
+	if len(points) == 0 {
+		return nil, nil
+	}
+
+	first, last := points[0], points[len(points)-1]
+
+	start, end := 0, len(points)-1
+	for start <= end && geo.Distance(points[start], first) < trimRadiusMeters {
+		start++
+	}
+	for end >= start && geo.Distance(points[end], last) < trimRadiusMeters {
+		end--
+	}
+	if start > end {
+		return nil, nil
+	}
+
+	zones := s.List(address)
+	appliedSet := make(map[string]struct{})
+
+	var segments [][]int
+	var current []int
+	for i := start; i <= end; i++ {
+		zoneID := intersectingZone(zones, points[i])
+		if zoneID != "" {
+			appliedSet[zoneID] = struct{}{}
+			if len(current) > 0 {
+				segments = append(segments, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, i)
+	}
+	if len(current) > 0 {
+		segments = append(segments, current)
+	}
+
+	applied := make([]string, 0, len(appliedSet))
+	for id := range appliedSet {
+		applied = append(applied, id)
+	}
+
+	return segments, applied
+}
+
+func intersectingZone(zones []Zone, p orb.Point) string {
+	for _, z := range zones {
+		if geo.Distance(p, orb.Point{z.Longitude, z.Latitude}) <= z.RadiusMeters {
+			return z.ID
+		}
+	}
+	return ""
+}
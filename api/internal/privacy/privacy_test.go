@@ -0,0 +1,117 @@
+package privacy
+
+import (
+	"testing"
+
+	"github.com/paulmach/orb"
+)
+
+func TestRedactIndicesSplitsOnMidTripZone(t *testing.T) {
+	s := NewStore()
+	s.Create("0xabc", Zone{Type: ZoneHome, Latitude: 1, Longitude: 0, RadiusMeters: 100})
+
+	// A straight line running north; the home zone sits around the
+	// midpoint, so the result should be two segments straddling it.
+	points := []orb.Point{
+		{0, 0},
+		{0, 0.5},
+		{0, 0.9999},
+		{0, 1},
+		{0, 1.0001},
+		{0, 1.5},
+		{0, 2},
+	}
+
+	segments, applied := s.RedactIndices("0xabc", points, 0)
+
+	if len(segments) != 2 {
+		t.Fatalf("len(segments) = %d, want 2", len(segments))
+	}
+	if got := []int{0, 1}; !equalInts(segments[0], got) {
+		t.Errorf("segments[0] = %v, want %v", segments[0], got)
+	}
+	if got := []int{5, 6}; !equalInts(segments[1], got) {
+		t.Errorf("segments[1] = %v, want %v", segments[1], got)
+	}
+	if len(applied) != 1 {
+		t.Fatalf("len(applied) = %d, want 1", len(applied))
+	}
+}
+
+func TestRedactIndicesTrimsEndpoints(t *testing.T) {
+	s := NewStore()
+
+	points := []orb.Point{
+		{0, 0},
+		{0, 0.0001},
+		{0, 1},
+		{0, 2},
+		{0, 2.0001},
+		{0, 2.0002},
+	}
+
+	segments, applied := s.RedactIndices("0xabc", points, 15)
+
+	if len(applied) != 0 {
+		t.Fatalf("len(applied) = %d, want 0", len(applied))
+	}
+	if len(segments) != 1 {
+		t.Fatalf("len(segments) = %d, want 1", len(segments))
+	}
+	if got := []int{2, 3}; !equalInts(segments[0], got) {
+		t.Errorf("segments[0] = %v, want %v (trimmed endpoints)", segments[0], got)
+	}
+}
+
+func TestRedactIndicesNoZonesNoTrim(t *testing.T) {
+	s := NewStore()
+
+	points := []orb.Point{{0, 0}, {0, 1}, {0, 2}}
+	segments, applied := s.RedactIndices("0xabc", points, 0)
+
+	if len(applied) != 0 {
+		t.Fatalf("len(applied) = %d, want 0", len(applied))
+	}
+	if len(segments) != 1 || !equalInts(segments[0], []int{0, 1, 2}) {
+		t.Fatalf("segments = %v, want [[0 1 2]]", segments)
+	}
+}
+
+func TestRedactMatchesRedactIndices(t *testing.T) {
+	s := NewStore()
+	s.Create("0xabc", Zone{Type: ZoneWork, Latitude: 1, Longitude: 0, RadiusMeters: 100})
+
+	points := []orb.Point{{0, 0}, {0, 0.5}, {0, 1}, {0, 1.5}, {0, 2}}
+
+	segments, applied := s.Redact("0xabc", points, 0)
+	indexSegments, indexApplied := s.RedactIndices("0xabc", points, 0)
+
+	if len(segments) != len(indexSegments) {
+		t.Fatalf("len(segments) = %d, len(indexSegments) = %d", len(segments), len(indexSegments))
+	}
+	for i, seg := range segments {
+		if len(seg) != len(indexSegments[i]) {
+			t.Fatalf("segments[%d] len = %d, indexSegments[%d] len = %d", i, len(seg), i, len(indexSegments[i]))
+		}
+		for j, p := range seg {
+			if p != points[indexSegments[i][j]] {
+				t.Errorf("segments[%d][%d] = %v, want %v", i, j, p, points[indexSegments[i][j]])
+			}
+		}
+	}
+	if len(applied) != len(indexApplied) {
+		t.Fatalf("len(applied) = %d, len(indexApplied) = %d", len(applied), len(indexApplied))
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,193 @@
+package sessionstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrFingerprintMismatch is returned by DoLockedAction when the store's
+// on-disk contents changed since the caller read its baseline
+// fingerprint, so the update was rejected to avoid clobbering another
+// instance's write.
+var ErrFingerprintMismatch = errors.New("sessionstore: fingerprint mismatch")
+
+// Entry is a single value as persisted to disk by FileStore.
+type Entry struct {
+	Value     string    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FileStore is a JSON file backed Store. Writes are atomic
+// (write-to-temp-then-rename) so a crash mid-write can't corrupt the
+// file, and Fingerprint/DoLockedAction provide optimistic concurrency so
+// multiple app instances sharing the same file don't clobber each
+// other's updates.
+type FileStore struct {
+	mu   *sync.Mutex
+	path string
+	ttl  time.Duration
+}
+
+// NewFileStore returns a FileStore persisting to path, with entries
+// expiring after ttl.
+func NewFileStore(path string, ttl time.Duration) *FileStore {
+	return &FileStore{mu: &sync.Mutex{}, path: path, ttl: ttl}
+}
+
+func (f *FileStore) readAll() ([]byte, map[string]Entry, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, map[string]Entry{}, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	entries := map[string]Entry{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, nil, err
+		}
+	}
+	return data, entries, nil
+}
+
+// writeAll atomically replaces the file's contents with entries.
+func (f *FileStore) writeAll(entries map[string]Entry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(f.path)
+	tmp, err := os.CreateTemp(dir, ".sessionstore-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
+
+func fingerprintOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Fingerprint returns a content hash of the file's current bytes, to be
+// passed as the baseline to a later DoLockedAction call.
+func (f *FileStore) Fingerprint() (string, error) {
+	fingerprint, _, err := f.snapshot()
+	return fingerprint, err
+}
+
+// snapshot returns the store's current entries along with the
+// fingerprint of the bytes they were read from.
+func (f *FileStore) snapshot() (string, map[string]Entry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, entries, err := f.readAll()
+	if err != nil {
+		return "", nil, err
+	}
+	return fingerprintOf(data), entries, nil
+}
+
+func (f *FileStore) Get(key string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, entries, err := f.readAll()
+	if err != nil {
+		return "", false
+	}
+	entry, ok := entries[key]
+	if !ok || time.Now().After(entry.ExpiresAt) {
+		return "", false
+	}
+	return entry.Value, true
+}
+
+func (f *FileStore) Set(key, value string) error {
+	return f.update(func(entries map[string]Entry) (map[string]Entry, error) {
+		entries[key] = Entry{Value: value, ExpiresAt: time.Now().Add(f.ttl)}
+		return entries, nil
+	})
+}
+
+func (f *FileStore) Delete(key string) error {
+	return f.update(func(entries map[string]Entry) (map[string]Entry, error) {
+		delete(entries, key)
+		return entries, nil
+	})
+}
+
+// update applies mutate to the store's current entries and persists the
+// result through DoLockedAction, retrying on ErrFingerprintMismatch so a
+// concurrent write from another instance sharing this file gets
+// re-applied against fresh data instead of silently overwritten.
+func (f *FileStore) update(mutate func(map[string]Entry) (map[string]Entry, error)) error {
+	for {
+		baseline, entries, err := f.snapshot()
+		if err != nil {
+			return err
+		}
+
+		updated, err := mutate(entries)
+		if err != nil {
+			return err
+		}
+
+		err = f.DoLockedAction(baseline, func(map[string]Entry) (map[string]Entry, error) {
+			return updated, nil
+		})
+		if errors.Is(err, ErrFingerprintMismatch) {
+			continue
+		}
+		return err
+	}
+}
+
+func (f *FileStore) WithTTL(ttl time.Duration) Store {
+	return &FileStore{mu: f.mu, path: f.path, ttl: ttl}
+}
+
+// DoLockedAction applies cb to the store's current entries and persists
+// the result, but only if the file's fingerprint still matches baseline
+// (as previously returned by Fingerprint). A baseline that no longer
+// matches means another instance wrote to the file in the meantime, so
+// the update is rejected with ErrFingerprintMismatch instead of
+// silently overwriting it.
+func (f *FileStore) DoLockedAction(baseline string, cb func(map[string]Entry) (map[string]Entry, error)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, entries, err := f.readAll()
+	if err != nil {
+		return err
+	}
+	if fingerprintOf(data) != baseline {
+		return ErrFingerprintMismatch
+	}
+
+	updated, err := cb(entries)
+	if err != nil {
+		return err
+	}
+
+	return f.writeAll(updated)
+}
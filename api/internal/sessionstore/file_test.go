@@ -0,0 +1,86 @@
+package sessionstore
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestFileStore(t *testing.T) *FileStore {
+	t.Helper()
+	dir := t.TempDir()
+	return NewFileStore(filepath.Join(dir, "store.json"), time.Hour)
+}
+
+func TestFileStoreSetGetDelete(t *testing.T) {
+	fs := newTestFileStore(t)
+
+	if err := fs.Set("a", "1"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if v, ok := fs.Get("a"); !ok || v != "1" {
+		t.Fatalf("Get(a) = %q, %v, want 1, true", v, ok)
+	}
+
+	if err := fs.Delete("a"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := fs.Get("a"); ok {
+		t.Fatal("Get(a) found after Delete, want miss")
+	}
+}
+
+func TestDoLockedActionRejectsStaleFingerprint(t *testing.T) {
+	fs := newTestFileStore(t)
+
+	baseline, err := fs.Fingerprint()
+	if err != nil {
+		t.Fatalf("Fingerprint: %v", err)
+	}
+
+	// Simulates another instance writing to the shared file after
+	// baseline was taken.
+	if err := fs.Set("other", "value"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	err = fs.DoLockedAction(baseline, func(entries map[string]Entry) (map[string]Entry, error) {
+		entries["mine"] = Entry{Value: "x", ExpiresAt: time.Now().Add(time.Hour)}
+		return entries, nil
+	})
+	if !errors.Is(err, ErrFingerprintMismatch) {
+		t.Fatalf("DoLockedAction err = %v, want ErrFingerprintMismatch", err)
+	}
+}
+
+func TestUpdateRetriesOnConcurrentWrite(t *testing.T) {
+	fs := newTestFileStore(t)
+
+	attempts := 0
+	err := fs.update(func(entries map[string]Entry) (map[string]Entry, error) {
+		attempts++
+		if attempts == 1 {
+			// A second instance races ahead of this update between its
+			// snapshot and its DoLockedAction call.
+			if err := fs.Set("race", "1"); err != nil {
+				t.Fatalf("concurrent Set: %v", err)
+			}
+		}
+		entries["mine"] = Entry{Value: "done", ExpiresAt: time.Now().Add(time.Hour)}
+		return entries, nil
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2 (one retry after the fingerprint mismatch)", attempts)
+	}
+
+	if v, ok := fs.Get("mine"); !ok || v != "done" {
+		t.Fatalf("Get(mine) = %q, %v, want done, true", v, ok)
+	}
+	if v, ok := fs.Get("race"); !ok || v != "1" {
+		t.Fatalf("Get(race) = %q, %v, want 1, true", v, ok)
+	}
+}
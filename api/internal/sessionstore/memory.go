@@ -0,0 +1,44 @@
+package sessionstore
+
+import (
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// MemoryStore is the in-process, non-durable Store implementation: the
+// original behavior of cacheInstance/CacheInstance before this package
+// existed. Good for local development; entries don't survive a restart
+// and aren't shared across replicas.
+type MemoryStore struct {
+	cache *cache.Cache
+	ttl   time.Duration
+}
+
+// NewMemoryStore returns a MemoryStore whose entries expire after ttl.
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{cache: cache.New(ttl, 2*ttl), ttl: ttl}
+}
+
+func (m *MemoryStore) Get(key string) (string, bool) {
+	v, found := m.cache.Get(key)
+	if !found {
+		return "", false
+	}
+	value, ok := v.(string)
+	return value, ok
+}
+
+func (m *MemoryStore) Set(key, value string) error {
+	m.cache.Set(key, value, m.ttl)
+	return nil
+}
+
+func (m *MemoryStore) Delete(key string) error {
+	m.cache.Delete(key)
+	return nil
+}
+
+func (m *MemoryStore) WithTTL(ttl time.Duration) Store {
+	return &MemoryStore{cache: m.cache, ttl: ttl}
+}
@@ -0,0 +1,52 @@
+package sessionstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// RedisStore is a Redis backed Store, for sharing session state across
+// replicas without standing up a shared filesystem.
+type RedisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisStore returns a RedisStore using client, with entries expiring
+// after ttl.
+func NewRedisStore(client *redis.Client, ttl time.Duration) *RedisStore {
+	return &RedisStore{client: client, ttl: ttl}
+}
+
+func (r *RedisStore) Get(key string) (string, bool) {
+	value, err := r.client.Get(context.Background(), key).Result()
+	switch {
+	case err == nil:
+		return value, true
+	case errors.Is(err, redis.Nil):
+		return "", false
+	default:
+		// A connection/infra error looks identical to a cache miss to
+		// every caller (queryTripsAPI, challenge lookups, ...), so log it
+		// distinctly rather than letting it masquerade as an expired
+		// session.
+		log.Error().Err(err).Str("key", key).Msg("sessionstore: redis get failed")
+		return "", false
+	}
+}
+
+func (r *RedisStore) Set(key, value string) error {
+	return r.client.Set(context.Background(), key, value, r.ttl).Err()
+}
+
+func (r *RedisStore) Delete(key string) error {
+	return r.client.Del(context.Background(), key).Err()
+}
+
+func (r *RedisStore) WithTTL(ttl time.Duration) Store {
+	return &RedisStore{client: r.client, ttl: ttl}
+}
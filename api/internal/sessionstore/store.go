@@ -0,0 +1,20 @@
+// Package sessionstore provides a pluggable, durable key/value store for
+// session cookies, privilege tokens, and pending auth challenges. The
+// in-process go-cache instances it replaces vanished on restart and
+// weren't shared across replicas; Store implementations can persist to
+// disk or a shared backend instead.
+package sessionstore
+
+import "time"
+
+// Store is a TTL-scoped key/value store. Values are opaque strings;
+// callers that need to store structured data (e.g. a pending challenge)
+// marshal it themselves before calling Set.
+type Store interface {
+	Get(key string) (string, bool)
+	Set(key, value string) error
+	Delete(key string) error
+	// WithTTL returns a store backed by the same data as the receiver,
+	// but whose Set calls use ttl as the expiration instead.
+	WithTTL(ttl time.Duration) Store
+}
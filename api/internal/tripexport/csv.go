@@ -0,0 +1,60 @@
+package tripexport
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geo"
+)
+
+// CSVEncoder renders a trip as timestamp,lat,lon,speed_mps rows. Speed is
+// computed between consecutive points within a segment; the first point
+// of every segment has no predecessor, so it gets speed 0.
+type CSVEncoder struct{}
+
+func (CSVEncoder) ContentType() string { return "text/csv" }
+func (CSVEncoder) Extension() string   { return "csv" }
+
+func (CSVEncoder) Encode(w io.Writer, meta Metadata, segments [][]Point) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"timestamp", "lat", "lon", "speed_mps"}); err != nil {
+		return err
+	}
+
+	for _, seg := range segments {
+		for i, p := range seg {
+			speed := 0.0
+			if i > 0 {
+				speed = speedBetween(seg[i-1], p)
+			}
+			timestamp := ""
+			if !p.Timestamp.IsZero() {
+				timestamp = p.Timestamp.UTC().Format(time.RFC3339)
+			}
+			record := []string{
+				timestamp,
+				strconv.FormatFloat(p.Latitude, 'f', -1, 64),
+				strconv.FormatFloat(p.Longitude, 'f', -1, 64),
+				strconv.FormatFloat(speed, 'f', -1, 64),
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func speedBetween(a, b Point) float64 {
+	dt := b.Timestamp.Sub(a.Timestamp).Seconds()
+	if dt <= 0 {
+		return 0
+	}
+	dist := geo.Distance(orb.Point{a.Longitude, a.Latitude}, orb.Point{b.Longitude, b.Latitude})
+	return dist / dt
+}
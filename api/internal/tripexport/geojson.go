@@ -0,0 +1,82 @@
+package tripexport
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	geojson "github.com/paulmach/go.geojson"
+)
+
+// GeoJSONEncoder renders a trip as a GeoJSON LineString (or
+// MultiLineString, when segments has more than one element), with each
+// coordinate's ISO-8601 timestamp carried in a parallel "timestamps"
+// property since GeoJSON coordinates can't hold extra fields, plus the
+// trip-level properties (privacy flags, distance/speed/stop metrics)
+// the map view has always shown.
+type GeoJSONEncoder struct{}
+
+func (GeoJSONEncoder) ContentType() string { return "application/geo+json" }
+func (GeoJSONEncoder) Extension() string   { return "geojson" }
+
+func (GeoJSONEncoder) Encode(w io.Writer, meta Metadata, segments [][]Point) error {
+	var feature *geojson.Feature
+	var timestamps [][]string
+
+	switch len(segments) {
+	case 0:
+		feature = geojson.NewLineStringFeature(nil)
+	case 1:
+		feature = geojson.NewLineStringFeature(coordsOf(segments[0]))
+		timestamps = [][]string{timestampsOf(segments[0])}
+	default:
+		lines := make([][][]float64, len(segments))
+		for i, seg := range segments {
+			lines[i] = coordsOf(seg)
+			timestamps = append(timestamps, timestampsOf(seg))
+		}
+		feature = geojson.NewMultiLineStringFeature(lines...)
+	}
+
+	feature.Properties = map[string]interface{}{
+		"type":              "LineString",
+		"trip_id":           meta.TripID,
+		"trip_start":        meta.TripStart,
+		"trip_end":          meta.TripEnd,
+		"privacy_zone":      len(meta.PrivacyApplied) > 0,
+		"privacy_applied":   meta.PrivacyApplied,
+		"color":             "black",
+		"point-color":       "black",
+		"timestamps":        timestamps,
+		"distance_meters":   meta.Metrics.TotalDistanceMeters,
+		"moving_duration_s": meta.Metrics.MovingDuration.Seconds(),
+		"idle_duration_s":   meta.Metrics.IdleDuration.Seconds(),
+		"mean_speed_mps":    meta.Metrics.MeanSpeedMPS,
+		"max_speed_mps":     meta.Metrics.MaxSpeedMPS,
+		"bounding_box":      meta.Metrics.BoundingBox,
+		"stops":             meta.Metrics.Stops,
+	}
+
+	fc := geojson.NewFeatureCollection()
+	fc.AddFeature(feature)
+
+	return json.NewEncoder(w).Encode(fc)
+}
+
+func coordsOf(points []Point) [][]float64 {
+	coords := make([][]float64, len(points))
+	for i, p := range points {
+		coords[i] = []float64{p.Longitude, p.Latitude}
+	}
+	return coords
+}
+
+func timestampsOf(points []Point) []string {
+	stamps := make([]string, len(points))
+	for i, p := range points {
+		if !p.Timestamp.IsZero() {
+			stamps[i] = p.Timestamp.UTC().Format(time.RFC3339)
+		}
+	}
+	return stamps
+}
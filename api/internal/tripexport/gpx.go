@@ -0,0 +1,59 @@
+package tripexport
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+type gpxTrkpt struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Time string  `xml:"time,omitempty"`
+}
+
+type gpxTrkseg struct {
+	Points []gpxTrkpt `xml:"trkpt"`
+}
+
+type gpxTrk struct {
+	Name string      `xml:"name,omitempty"`
+	Segs []gpxTrkseg `xml:"trkseg"`
+}
+
+type gpxDoc struct {
+	XMLName xml.Name `xml:"gpx"`
+	Version string   `xml:"version,attr"`
+	Creator string   `xml:"creator,attr"`
+	Trk     gpxTrk   `xml:"trk"`
+}
+
+// GPXEncoder renders a trip as GPX 1.1.
+type GPXEncoder struct{}
+
+func (GPXEncoder) ContentType() string { return "application/gpx+xml" }
+func (GPXEncoder) Extension() string   { return "gpx" }
+
+func (GPXEncoder) Encode(w io.Writer, meta Metadata, segments [][]Point) error {
+	doc := gpxDoc{Version: "1.1", Creator: "trips-web-app", Trk: gpxTrk{Name: meta.TripID}}
+
+	for _, seg := range segments {
+		trkseg := gpxTrkseg{Points: make([]gpxTrkpt, 0, len(seg))}
+		for _, p := range seg {
+			pt := gpxTrkpt{Lat: p.Latitude, Lon: p.Longitude}
+			if !p.Timestamp.IsZero() {
+				pt.Time = p.Timestamp.UTC().Format(time.RFC3339)
+			}
+			trkseg.Points = append(trkseg.Points, pt)
+		}
+		doc.Trk.Segs = append(doc.Trk.Segs, trkseg)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,52 @@
+package tripexport
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// KMLEncoder renders a trip as KML 2.2, one Placemark/LineString per
+// segment.
+type KMLEncoder struct{}
+
+func (KMLEncoder) ContentType() string { return "application/vnd.google-earth.kml+xml" }
+func (KMLEncoder) Extension() string   { return "kml" }
+
+func (KMLEncoder) Encode(w io.Writer, meta Metadata, segments [][]Point) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "<kml xmlns=\"http://www.opengis.net/kml/2.2\">\n<Document>\n"); err != nil {
+		return err
+	}
+
+	for i, seg := range segments {
+		name := xmlEscapeText(meta.TripID)
+		if len(segments) > 1 {
+			name = fmt.Sprintf("%s-%d", name, i+1)
+		}
+		if _, err := fmt.Fprintf(w, "<Placemark>\n<name>%s</name>\n<LineString>\n<coordinates>\n", name); err != nil {
+			return err
+		}
+		for _, p := range seg {
+			if _, err := io.WriteString(w, strconv.FormatFloat(p.Longitude, 'f', -1, 64)+","+strconv.FormatFloat(p.Latitude, 'f', -1, 64)+",0\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, "</coordinates>\n</LineString>\n</Placemark>\n"); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "</Document>\n</kml>\n")
+	return err
+}
+
+func xmlEscapeText(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
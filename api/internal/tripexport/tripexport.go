@@ -0,0 +1,60 @@
+// Package tripexport renders a trip's points into GPX, KML, CSV, or
+// GeoJSON, so the same trip data can be exported in whichever format the
+// caller negotiates via the Accept header or a ?format= query param.
+package tripexport
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dimo-network/trips-web-app-new/api/trips/analytics"
+)
+
+// Point is a single timestamped location, optionally annotated with the
+// instantaneous speed leading into it.
+type Point struct {
+	Latitude  float64
+	Longitude float64
+	Timestamp time.Time
+	SpeedMPS  float64
+}
+
+// Metadata is trip-level context available to an Encoder alongside its
+// points. GPX/KML only use TripID and CSV uses none of it; GeoJSONEncoder surfaces the rest
+// as feature properties, matching what the map view has always shown.
+type Metadata struct {
+	TripID         string
+	TripStart      string
+	TripEnd        string
+	PrivacyApplied []string
+	Metrics        analytics.Metrics
+}
+
+// Encoder serializes a trip to w. Segments is almost always a single
+// slice of points; it has more than one element when privacy-zone
+// redaction split the trip's route.
+type Encoder interface {
+	// ContentType is the MIME type to send with the response.
+	ContentType() string
+	// Extension is the file extension for this format, without a dot.
+	Extension() string
+	Encode(w io.Writer, meta Metadata, segments [][]Point) error
+}
+
+// EncoderFor resolves a format name (case-insensitive, e.g. from a
+// ?format= query param or a parsed Accept header) to its Encoder.
+func EncoderFor(format string) (Encoder, bool) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "geojson", "application/geo+json", "application/json":
+		return GeoJSONEncoder{}, true
+	case "gpx", "application/gpx+xml":
+		return GPXEncoder{}, true
+	case "kml", "application/vnd.google-earth.kml+xml":
+		return KMLEncoder{}, true
+	case "csv", "text/csv":
+		return CSVEncoder{}, true
+	default:
+		return nil, false
+	}
+}
@@ -6,25 +6,19 @@ import (
 	"fmt"
 	"github.com/DIMO-Network/shared"
 	"github.com/dimo-network/trips-web-app-new/api/api/internal/config"
+	"github.com/dimo-network/trips-web-app-new/api/internal/httpclient"
 	jwtware "github.com/gofiber/contrib/jwt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/template/handlebars/v2"
-	"github.com/google/uuid"
-	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
-	"io"
 	"net/http"
-	"net/url"
 	"os"
-	"strings"
 	"time"
 )
 
-var cacheInstance = cache.New(cache.DefaultExpiration, 10*time.Minute)
-
 type ChallengeResponse struct {
 	State     string `json:"state"`
 	Challenge string `json:"challenge"`
@@ -59,7 +53,7 @@ func HandleGetVehicles(c *fiber.Ctx, settings *config.Settings) error {
 	}
 
 	// Query identity-api
-	vehicles, err := queryIdentityAPIForVehicles(ethAddress, settings)
+	vehicles, err := queryIdentityAPIForVehicles(c, ethAddress, settings)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).SendString("Error querying identity API: " + err.Error())
 	}
@@ -70,7 +64,7 @@ func HandleGetVehicles(c *fiber.Ctx, settings *config.Settings) error {
 	})
 }
 
-func queryIdentityAPIForVehicles(ethAddress string, settings *config.Settings) ([]Vehicle, error) {
+func queryIdentityAPIForVehicles(c *fiber.Ctx, ethAddress string, settings *config.Settings) ([]Vehicle, error) {
 	// GraphQL query
 	graphqlQuery := `{
         vehicles(first: 10, filterBy: { owner: "` + ethAddress + `" }) {
@@ -103,14 +97,13 @@ func queryIdentityAPIForVehicles(ethAddress string, settings *config.Settings) (
 	}
 
 	// POST request
-	req, err := http.NewRequest("POST", settings.IdentityAPIURL, bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(c.UserContext(), "POST", settings.IdentityAPIURL, bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := apiHTTPClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -124,101 +117,6 @@ func queryIdentityAPIForVehicles(ethAddress string, settings *config.Settings) (
 	return response.Data.Vehicles.Nodes, nil
 }
 
-func HandleGenerateChallenge(c *fiber.Ctx, settings *config.Settings) error {
-	address := c.FormValue("address")
-
-	formData := url.Values{}
-	formData.Add("client_id", settings.ClientID)
-	formData.Add("domain", settings.Domain)
-	formData.Add("scope", settings.Scope)
-	formData.Add("response_type", settings.ResponseType)
-	formData.Add("address", address)
-
-	encodedFormData := formData.Encode()
-	reqURL := settings.AuthURL
-
-	resp, err := http.Post(reqURL, "application/x-www-form-urlencoded", strings.NewReader(encodedFormData))
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to make request to external service")
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Error reading external response")
-	}
-
-	var apiResp ChallengeResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Error processing response from external service")
-	}
-
-	if apiResp.State == "" || apiResp.Challenge == "" {
-		return c.Status(fiber.StatusInternalServerError).SendString("State or Challenge incomplete from external service")
-	}
-
-	return c.JSON(apiResp)
-}
-
-func HandleSubmitChallenge(c *fiber.Ctx, settings *config.Settings) error {
-	state := c.FormValue("state")
-	signature := c.FormValue("signature")
-
-	log.Info().Msgf("State: %s, Signature: %s", state, signature)
-
-	formData := url.Values{}
-	formData.Add("client_id", settings.ClientID)
-	formData.Add("domain", settings.Domain)
-	formData.Add("grant_type", settings.GrantType)
-	formData.Add("state", state)
-	formData.Add("signature", signature)
-
-	encodedFormData := formData.Encode()
-	reqURL := settings.SubmitChallengeURL
-
-	resp, err := http.Post(reqURL, "application/x-www-form-urlencoded", strings.NewReader(encodedFormData))
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to make request to external service")
-	}
-	defer resp.Body.Close()
-
-	// Check the HTTP status code here
-	if resp.StatusCode >= 300 {
-		return c.Status(fiber.StatusInternalServerError).SendString(fmt.Sprintf("Received non-success status code: %d", resp.StatusCode))
-	}
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to read response from external service")
-	}
-
-	var responseMap map[string]interface{}
-	if err := json.Unmarshal(respBody, &responseMap); err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Error processing response")
-	}
-
-	log.Info().Msgf("Response from submit challenge: %+v", responseMap) //debugging
-
-	token, exists := responseMap["access_token"]
-	if !exists {
-		return c.Status(fiber.StatusInternalServerError).SendString("Token not found in response")
-	}
-
-	sessionID := uuid.New().String()
-	cacheInstance.Set(sessionID, token, 2*time.Hour)
-
-	cookie := new(fiber.Cookie)
-	cookie.Name = "session_id"
-	cookie.Value = sessionID
-	cookie.Expires = time.Now().Add(2 * time.Hour)
-	cookie.HTTPOnly = true
-	cookie.Domain = "localhost"
-
-	c.Cookie(cookie)
-
-	return c.JSON(fiber.Map{"message": "Challenge accepted and session started!", "access_token": token})
-}
-
 func ErrorHandler(ctx *fiber.Ctx, err error) error {
 	code := fiber.StatusInternalServerError
 	message := "Internal Server Error"
@@ -253,6 +151,12 @@ func main() {
 	}
 	zerolog.SetGlobalLevel(level)
 
+	cacheInstance = newSessionStore(&settings, settings.SessionStoreFilePath, 2*time.Hour)
+	CacheInstance = newSessionStore(&settings, settings.PrivilegeStoreFilePath, 2*time.Hour)
+	challengeStore = newSessionStore(&settings, settings.ChallengeStoreFilePath, 5*time.Minute)
+
+	apiHTTPClient = httpclient.New(&settings)
+
 	engine := handlebars.New("../views", ".hbs")
 
 	app := fiber.New(fiber.Config{
@@ -280,6 +184,19 @@ func main() {
 	app.Get("/api/vehicles/me", jwtMiddleware, func(c *fiber.Ctx) error {
 		return HandleGetVehicles(c, &settings)
 	})
+	app.Get("/api/vehicles/:tokenId/trips/:tripId/stats", jwtMiddleware, func(c *fiber.Ctx) error {
+		return HandleTripStats(c, &settings)
+	})
+	app.Get("/api/vehicles/:tokenId/trips/:tripId/map-data", jwtMiddleware, func(c *fiber.Ctx) error {
+		return handleMapDataForTrip(c, &settings, c.Params("tripId"), c.Query("startTime"), c.Query("endTime"))
+	})
+	app.Get("/api/vehicles/:tokenId/trips.zip", jwtMiddleware, func(c *fiber.Ctx) error {
+		return HandleExportTripsZip(c, &settings)
+	})
+	app.Get("/api/privacy/zones", jwtMiddleware, HandleListPrivacyZones)
+	app.Post("/api/privacy/zones", jwtMiddleware, HandleCreatePrivacyZone)
+	app.Put("/api/privacy/zones/:zoneId", jwtMiddleware, HandleUpdatePrivacyZone)
+	app.Delete("/api/privacy/zones/:zoneId", jwtMiddleware, HandleDeletePrivacyZone)
 
 	// Public Routes
 	app.Post("/auth/web3/generate_challenge", func(c *fiber.Ctx) error {
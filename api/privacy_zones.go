@@ -0,0 +1,66 @@
+package main
+
+import (
+	"github.com/dimo-network/trips-web-app-new/api/internal/privacy"
+	"github.com/gofiber/fiber/v2"
+)
+
+// privacyZoneStore holds every user's configured privacy zones for the
+// lifetime of the process.
+var privacyZoneStore = privacy.NewStore()
+
+func HandleListPrivacyZones(c *fiber.Ctx) error {
+	ethAddress, ok := c.Locals(EthereumAddressKey).(string)
+	if !ok || ethAddress == "" {
+		return c.Status(fiber.StatusBadRequest).SendString("Ethereum address is required")
+	}
+
+	return c.JSON(privacyZoneStore.List(ethAddress))
+}
+
+func HandleCreatePrivacyZone(c *fiber.Ctx) error {
+	ethAddress, ok := c.Locals(EthereumAddressKey).(string)
+	if !ok || ethAddress == "" {
+		return c.Status(fiber.StatusBadRequest).SendString("Ethereum address is required")
+	}
+
+	var zone privacy.Zone
+	if err := c.BodyParser(&zone); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid zone payload")
+	}
+
+	created := privacyZoneStore.Create(ethAddress, zone)
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+func HandleUpdatePrivacyZone(c *fiber.Ctx) error {
+	ethAddress, ok := c.Locals(EthereumAddressKey).(string)
+	if !ok || ethAddress == "" {
+		return c.Status(fiber.StatusBadRequest).SendString("Ethereum address is required")
+	}
+
+	var zone privacy.Zone
+	if err := c.BodyParser(&zone); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid zone payload")
+	}
+	zone.ID = c.Params("zoneId")
+
+	if err := privacyZoneStore.Update(ethAddress, zone); err != nil {
+		return c.Status(fiber.StatusNotFound).SendString(err.Error())
+	}
+
+	return c.JSON(zone)
+}
+
+func HandleDeletePrivacyZone(c *fiber.Ctx) error {
+	ethAddress, ok := c.Locals(EthereumAddressKey).(string)
+	if !ok || ethAddress == "" {
+		return c.Status(fiber.StatusBadRequest).SendString("Ethereum address is required")
+	}
+
+	if err := privacyZoneStore.Delete(ethAddress, c.Params("zoneId")); err != nil {
+		return c.Status(fiber.StatusNotFound).SendString(err.Error())
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"time"
+
+	"github.com/dimo-network/trips-web-app-new/api/api/internal/config"
+	"github.com/dimo-network/trips-web-app-new/api/internal/sessionstore"
+	"github.com/redis/go-redis/v9"
+)
+
+// cacheInstance maps session_id -> access_token, and CacheInstance maps
+// privilegeToken_<session_id> -> privilege token. Both default to an
+// in-memory store so the app still runs without any extra setup; main()
+// replaces them with whatever backend settings.SessionStoreBackend
+// selects once settings are loaded.
+var cacheInstance sessionstore.Store = sessionstore.NewMemoryStore(2 * time.Hour)
+var CacheInstance sessionstore.Store = sessionstore.NewMemoryStore(2 * time.Hour)
+
+// newSessionStore builds the sessionstore.Store backend selected by
+// settings.SessionStoreBackend ("memory", "file", or "redis"), defaulting
+// to memory when unset.
+func newSessionStore(settings *config.Settings, filePath string, ttl time.Duration) sessionstore.Store {
+	switch settings.SessionStoreBackend {
+	case "redis":
+		client := redis.NewClient(&redis.Options{Addr: settings.RedisAddr})
+		return sessionstore.NewRedisStore(client, ttl)
+	case "file":
+		return sessionstore.NewFileStore(filePath, ttl)
+	default:
+		return sessionstore.NewMemoryStore(ttl)
+	}
+}
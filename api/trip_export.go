@@ -0,0 +1,137 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"strconv"
+
+	"github.com/dimo-network/trips-web-app-new/api/api/internal/config"
+	"github.com/dimo-network/trips-web-app-new/api/internal/tripexport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geo"
+	"github.com/rs/zerolog/log"
+)
+
+// negotiateExportFormat resolves the caller's requested trip export
+// format from a ?format= query param, falling back to the Accept header,
+// and defaulting to GeoJSON when there is no ?format= param and the
+// Accept header names no supported format. A ?format= value is returned
+// as given, even when it names no supported format.
+func negotiateExportFormat(c *fiber.Ctx) string {
+	if format := c.Query("format"); format != "" {
+		return format
+	}
+
+	switch c.Accepts("application/geo+json", "application/gpx+xml", "application/vnd.google-earth.kml+xml", "text/csv") {
+	case "application/gpx+xml":
+		return "gpx"
+	case "application/vnd.google-earth.kml+xml":
+		return "kml"
+	case "text/csv":
+		return "csv"
+	default:
+		return "geojson"
+	}
+}
+
+// toExportSegments re-associates the original, timestamped locations
+// with the surviving indices RedactIndices returned, and computes the
+// instantaneous speed into each point from its predecessor within the
+// same segment.
+func toExportSegments(locations []LocationData, indexSegments [][]int) [][]tripexport.Point {
+	segments := make([][]tripexport.Point, len(indexSegments))
+	for i, idxs := range indexSegments {
+		points := make([]tripexport.Point, len(idxs))
+		for j, idx := range idxs {
+			loc := locations[idx]
+
+			var speed float64
+			if j > 0 {
+				prev := locations[idxs[j-1]]
+				if dt := loc.Timestamp.Sub(prev.Timestamp).Seconds(); dt > 0 {
+					dist := geo.Distance(orb.Point{prev.Longitude, prev.Latitude}, orb.Point{loc.Longitude, loc.Latitude})
+					speed = dist / dt
+				}
+			}
+
+			points[j] = tripexport.Point{
+				Latitude:  loc.Latitude,
+				Longitude: loc.Longitude,
+				Timestamp: loc.Timestamp,
+				SpeedMPS:  speed,
+			}
+		}
+		segments[i] = points
+	}
+	return segments
+}
+
+// buildExport applies the caller's privacy zones and endpoint trimming
+// to locations, then assembles everything an Encoder needs: the
+// surviving, timestamped segments and the trip-level Metadata (privacy
+// flags, distance/speed/stop metrics) derived from that same redacted
+// data. This is the single path every export format and both the
+// single-trip and bulk-zip endpoints go through, so a trip never
+// serializes differently depending on which route produced it.
+func buildExport(locations []LocationData, tripID, tripStart, tripEnd, ethAddress string, settings *config.Settings) ([][]tripexport.Point, tripexport.Metadata) {
+	points := pointsOf(locations)
+	indexSegments, appliedZones := privacyZoneStore.RedactIndices(ethAddress, points, settings.PrivacyTrimRadiusMeters)
+
+	meta := tripexport.Metadata{
+		TripID:         tripID,
+		TripStart:      tripStart,
+		TripEnd:        tripEnd,
+		PrivacyApplied: appliedZones,
+		Metrics:        tripMetrics(locations, indexSegments),
+	}
+	return toExportSegments(locations, indexSegments), meta
+}
+
+// HandleExportTripsZip streams every trip for a vehicle as one file per
+// trip, in the negotiated format, inside a single zip archive. A trip
+// whose history can't be fetched is logged and left out of the archive.
+func HandleExportTripsZip(c *fiber.Ctx, settings *config.Settings) error {
+	tokenID, err := strconv.ParseInt(c.Params("tokenId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid tokenId")
+	}
+
+	encoder, ok := tripexport.EncoderFor(negotiateExportFormat(c))
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).SendString("Unsupported export format")
+	}
+
+	trips, err := queryTripsAPI(tokenID, settings, c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch trips: " + err.Error()})
+	}
+
+	ethAddress, _ := c.Locals(EthereumAddressKey).(string)
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="vehicle-%d-trips.zip"`, tokenID))
+
+	zw := zip.NewWriter(c.Response().BodyWriter())
+	defer zw.Close()
+
+	for _, trip := range trips {
+		locations, err := queryDeviceDataHistory(tokenID, trip.Start.Time, trip.End.Time, settings, c)
+		if err != nil {
+			log.Error().Err(err).Str("trip_id", trip.ID).Msg("Failed to fetch trip history for export")
+			continue
+		}
+
+		entry, err := zw.Create(fmt.Sprintf("%s.%s", trip.ID, encoder.Extension()))
+		if err != nil {
+			return err
+		}
+
+		segments, meta := buildExport(locations, trip.ID, trip.Start.Time, trip.End.Time, ethAddress, settings)
+		if err := encoder.Encode(entry, meta, segments); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
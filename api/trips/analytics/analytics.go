@@ -0,0 +1,298 @@
+// Package analytics computes trip-level metrics (distance, speed, stops)
+// from a sequence of timestamped location fixes, and helps snap those
+// fixes onto a candidate route.
+package analytics
+
+import (
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geo"
+)
+
+// Default stop-detection thresholds: a stop is a contiguous window of at
+// least StopMinPoints fixes that never strays more than StopRadiusMeters
+// from its centroid for at least StopMinDuration.
+const (
+	DefaultStopRadiusMeters = 30.0
+	DefaultStopMinDuration  = 120 * time.Second
+	DefaultStopMinPoints    = 3
+)
+
+// Fix is a single timestamped location reading.
+type Fix struct {
+	Point     orb.Point
+	Timestamp time.Time
+}
+
+// Stop is a detected period where the device stayed within StopRadiusMeters
+// of a centroid for at least StopMinDuration.
+type Stop struct {
+	Center     orb.Point
+	Start      time.Time
+	End        time.Time
+	PointCount int
+}
+
+// BoundingBox is the smallest axis-aligned box containing every fix.
+type BoundingBox struct {
+	Min orb.Point
+	Max orb.Point
+}
+
+// Metrics summarizes a trip computed from its fixes.
+type Metrics struct {
+	TotalDistanceMeters float64
+	MovingDuration      time.Duration
+	IdleDuration        time.Duration
+	MeanSpeedMPS        float64
+	MaxSpeedMPS         float64
+	BoundingBox         BoundingBox
+	Stops               []Stop
+}
+
+// Options tunes stop detection. Zero-value fields fall back to the
+// package defaults in Compute.
+type Options struct {
+	StopRadiusMeters float64
+	StopMinDuration  time.Duration
+	StopMinPoints    int
+}
+
+// DefaultOptions returns the recommended stop-detection thresholds.
+func DefaultOptions() Options {
+	return Options{
+		StopRadiusMeters: DefaultStopRadiusMeters,
+		StopMinDuration:  DefaultStopMinDuration,
+		StopMinPoints:    DefaultStopMinPoints,
+	}
+}
+
+func (o Options) withDefaults() Options {
+	if o.StopRadiusMeters <= 0 {
+		o.StopRadiusMeters = DefaultStopRadiusMeters
+	}
+	if o.StopMinDuration <= 0 {
+		o.StopMinDuration = DefaultStopMinDuration
+	}
+	if o.StopMinPoints <= 0 {
+		o.StopMinPoints = DefaultStopMinPoints
+	}
+	return o
+}
+
+// Compute derives distance, speed, and stop metrics from fixes, which must
+// already be sorted by Timestamp ascending.
+func Compute(fixes []Fix, opts Options) Metrics {
+	opts = opts.withDefaults()
+
+	var m Metrics
+	if len(fixes) == 0 {
+		return m
+	}
+
+	m.BoundingBox = boundingBox(fixes)
+
+	const movingSpeedThresholdMPS = 0.5
+
+	for i := 1; i < len(fixes); i++ {
+		prev, cur := fixes[i-1], fixes[i]
+		dist := geo.Distance(prev.Point, cur.Point)
+		dt := cur.Timestamp.Sub(prev.Timestamp)
+
+		m.TotalDistanceMeters += dist
+
+		if dt <= 0 {
+			continue
+		}
+		speed := dist / dt.Seconds()
+		if speed > m.MaxSpeedMPS {
+			m.MaxSpeedMPS = speed
+		}
+		if speed >= movingSpeedThresholdMPS {
+			m.MovingDuration += dt
+		} else {
+			m.IdleDuration += dt
+		}
+	}
+
+	totalDuration := m.MovingDuration + m.IdleDuration
+	if totalDuration > 0 {
+		m.MeanSpeedMPS = m.TotalDistanceMeters / totalDuration.Seconds()
+	}
+
+	m.Stops = detectStops(fixes, opts)
+
+	return m
+}
+
+// MergeMetrics combines metrics computed independently for disjoint,
+// non-empty segments of the same trip — e.g. after privacy-zone
+// redaction split a route into multiple pieces — into a single summary,
+// so callers never need to fall back to computing Metrics from the
+// unredacted fixes.
+func MergeMetrics(segments []Metrics) Metrics {
+	var m Metrics
+
+	for i, seg := range segments {
+		m.TotalDistanceMeters += seg.TotalDistanceMeters
+		m.MovingDuration += seg.MovingDuration
+		m.IdleDuration += seg.IdleDuration
+		if seg.MaxSpeedMPS > m.MaxSpeedMPS {
+			m.MaxSpeedMPS = seg.MaxSpeedMPS
+		}
+		m.Stops = append(m.Stops, seg.Stops...)
+
+		if i == 0 {
+			m.BoundingBox = seg.BoundingBox
+			continue
+		}
+		if seg.BoundingBox.Min.Lon() < m.BoundingBox.Min.Lon() {
+			m.BoundingBox.Min[0] = seg.BoundingBox.Min.Lon()
+		}
+		if seg.BoundingBox.Min.Lat() < m.BoundingBox.Min.Lat() {
+			m.BoundingBox.Min[1] = seg.BoundingBox.Min.Lat()
+		}
+		if seg.BoundingBox.Max.Lon() > m.BoundingBox.Max.Lon() {
+			m.BoundingBox.Max[0] = seg.BoundingBox.Max.Lon()
+		}
+		if seg.BoundingBox.Max.Lat() > m.BoundingBox.Max.Lat() {
+			m.BoundingBox.Max[1] = seg.BoundingBox.Max.Lat()
+		}
+	}
+
+	totalDuration := m.MovingDuration + m.IdleDuration
+	if totalDuration > 0 {
+		m.MeanSpeedMPS = m.TotalDistanceMeters / totalDuration.Seconds()
+	}
+
+	return m
+}
+
+func boundingBox(fixes []Fix) BoundingBox {
+	box := BoundingBox{Min: fixes[0].Point, Max: fixes[0].Point}
+	for _, f := range fixes[1:] {
+		if f.Point.Lon() < box.Min.Lon() {
+			box.Min[0] = f.Point.Lon()
+		}
+		if f.Point.Lat() < box.Min.Lat() {
+			box.Min[1] = f.Point.Lat()
+		}
+		if f.Point.Lon() > box.Max.Lon() {
+			box.Max[0] = f.Point.Lon()
+		}
+		if f.Point.Lat() > box.Max.Lat() {
+			box.Max[1] = f.Point.Lat()
+		}
+	}
+	return box
+}
+
+// detectStops sweeps the fixes and emits a Stop for every contiguous
+// window of at least StopMinPoints fixes that stays within
+// StopRadiusMeters of its centroid for at least StopMinDuration.
+func detectStops(fixes []Fix, opts Options) []Stop {
+	var stops []Stop
+
+	windowStart := 0
+	for windowStart < len(fixes) {
+		windowEnd := windowStart
+		centroid := fixes[windowStart].Point
+
+		for windowEnd+1 < len(fixes) {
+			candidate := windowEnd + 1
+			c := centroidOf(fixes[windowStart : candidate+1])
+			if !withinRadius(fixes[windowStart:candidate+1], c, opts.StopRadiusMeters) {
+				break
+			}
+			windowEnd = candidate
+			centroid = c
+		}
+
+		pointCount := windowEnd - windowStart + 1
+		duration := fixes[windowEnd].Timestamp.Sub(fixes[windowStart].Timestamp)
+		if pointCount >= opts.StopMinPoints && duration >= opts.StopMinDuration {
+			stops = append(stops, Stop{
+				Center:     centroid,
+				Start:      fixes[windowStart].Timestamp,
+				End:        fixes[windowEnd].Timestamp,
+				PointCount: pointCount,
+			})
+			windowStart = windowEnd + 1
+		} else {
+			windowStart++
+		}
+	}
+
+	return stops
+}
+
+func centroidOf(fixes []Fix) orb.Point {
+	var lon, lat float64
+	for _, f := range fixes {
+		lon += f.Point.Lon()
+		lat += f.Point.Lat()
+	}
+	n := float64(len(fixes))
+	return orb.Point{lon / n, lat / n}
+}
+
+func withinRadius(fixes []Fix, center orb.Point, radiusMeters float64) bool {
+	for _, f := range fixes {
+		if geo.Distance(f.Point, center) > radiusMeters {
+			return false
+		}
+	}
+	return true
+}
+
+// SnapResult is the closest point on a candidate route for a single fix.
+type SnapResult struct {
+	SegmentIndex   int
+	DistanceMeters float64
+	Projected      orb.Point
+}
+
+// SnapToSegments projects each fix onto the nearest segment of route,
+// using the classic clamp-to-[0,1] parametric projection, and returns the
+// closest segment index and perpendicular distance for every fix.
+func SnapToSegments(fixes []Fix, route orb.LineString) []SnapResult {
+	results := make([]SnapResult, len(fixes))
+
+	for i, f := range fixes {
+		best := SnapResult{SegmentIndex: -1, DistanceMeters: -1}
+
+		for seg := 0; seg+1 < len(route); seg++ {
+			projected := projectOntoSegment(f.Point, route[seg], route[seg+1])
+			dist := geo.Distance(f.Point, projected)
+			if best.SegmentIndex == -1 || dist < best.DistanceMeters {
+				best = SnapResult{SegmentIndex: seg, DistanceMeters: dist, Projected: projected}
+			}
+		}
+
+		results[i] = best
+	}
+
+	return results
+}
+
+// projectOntoSegment projects p onto the segment a->b, clamping the
+// parametric t to [0, 1] so the result always lies on the segment.
+func projectOntoSegment(p, a, b orb.Point) orb.Point {
+	abx, aby := b.Lon()-a.Lon(), b.Lat()-a.Lat()
+	apx, apy := p.Lon()-a.Lon(), p.Lat()-a.Lat()
+
+	abLenSq := abx*abx + aby*aby
+	if abLenSq == 0 {
+		return a
+	}
+
+	t := (apx*abx + apy*aby) / abLenSq
+	if t < 0 {
+		t = 0
+	} else if t > 1 {
+		t = 1
+	}
+
+	return orb.Point{a.Lon() + t*abx, a.Lat() + t*aby}
+}
@@ -0,0 +1,116 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/paulmach/orb"
+)
+
+func fix(lon, lat float64, t time.Time) Fix {
+	return Fix{Point: orb.Point{lon, lat}, Timestamp: t}
+}
+
+func TestComputeDistanceAndSpeed(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Two fixes roughly 111.3km apart (1 degree of latitude), 1000s apart.
+	fixes := []Fix{
+		fix(0, 0, start),
+		fix(0, 1, start.Add(1000*time.Second)),
+	}
+
+	m := Compute(fixes, DefaultOptions())
+
+	const wantDistance = 111319.5 // geo.Distance(0,0 -> 0,1) with orb.EarthRadius = 6378137
+	if diff := math.Abs(m.TotalDistanceMeters - wantDistance); diff > 100 {
+		t.Fatalf("TotalDistanceMeters = %v, want ~%v", m.TotalDistanceMeters, wantDistance)
+	}
+
+	wantSpeed := m.TotalDistanceMeters / 1000
+	if diff := math.Abs(m.MeanSpeedMPS - wantSpeed); diff > 0.01 {
+		t.Fatalf("MeanSpeedMPS = %v, want %v", m.MeanSpeedMPS, wantSpeed)
+	}
+	if m.MovingDuration != 1000*time.Second {
+		t.Fatalf("MovingDuration = %v, want 1000s", m.MovingDuration)
+	}
+}
+
+func TestComputeDetectsStop(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var fixes []Fix
+	// Parked for 3 minutes, one fix every 30s, all within a few meters.
+	for i := 0; i < 7; i++ {
+		fixes = append(fixes, fix(0, 0, start.Add(time.Duration(i)*30*time.Second)))
+	}
+	// Then drive away.
+	fixes = append(fixes, fix(0, 1, start.Add(10*time.Minute)))
+
+	m := Compute(fixes, DefaultOptions())
+
+	if len(m.Stops) != 1 {
+		t.Fatalf("len(Stops) = %d, want 1", len(m.Stops))
+	}
+	stop := m.Stops[0]
+	if stop.PointCount != 7 {
+		t.Errorf("PointCount = %d, want 7", stop.PointCount)
+	}
+	if stop.Start != fixes[0].Timestamp || stop.End != fixes[6].Timestamp {
+		t.Errorf("Stop = [%v, %v], want [%v, %v]", stop.Start, stop.End, fixes[0].Timestamp, fixes[6].Timestamp)
+	}
+}
+
+func TestComputeNoStopWhenMoving(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var fixes []Fix
+	for i := 0; i < 10; i++ {
+		fixes = append(fixes, fix(0, float64(i)*0.01, start.Add(time.Duration(i)*30*time.Second)))
+	}
+
+	m := Compute(fixes, DefaultOptions())
+	if len(m.Stops) != 0 {
+		t.Fatalf("len(Stops) = %d, want 0", len(m.Stops))
+	}
+}
+
+func TestMergeMetrics(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	segA := Compute([]Fix{fix(0, 0, start), fix(0, 1, start.Add(time.Minute))}, DefaultOptions())
+	segB := Compute([]Fix{fix(10, 10, start), fix(10, 9, start.Add(time.Minute))}, DefaultOptions())
+
+	merged := MergeMetrics([]Metrics{segA, segB})
+
+	wantDistance := segA.TotalDistanceMeters + segB.TotalDistanceMeters
+	if diff := math.Abs(merged.TotalDistanceMeters - wantDistance); diff > 0.01 {
+		t.Fatalf("TotalDistanceMeters = %v, want %v", merged.TotalDistanceMeters, wantDistance)
+	}
+
+	// The merged bounding box must cover both disjoint segments, not just
+	// the last one computed.
+	if merged.BoundingBox.Min.Lat() != 0 || merged.BoundingBox.Max.Lat() != 10 {
+		t.Fatalf("BoundingBox = %+v, want lat range [0, 10]", merged.BoundingBox)
+	}
+}
+
+func TestSnapToSegmentsClampsToEndpoints(t *testing.T) {
+	route := orb.LineString{{0, 0}, {0, 1}}
+
+	// A fix "before" the segment's start and one "after" its end should
+	// both clamp to the nearest endpoint rather than extrapolating.
+	fixes := []Fix{
+		fix(0, -1, time.Time{}),
+		fix(0, 2, time.Time{}),
+	}
+
+	results := SnapToSegments(fixes, route)
+	if results[0].Projected != (orb.Point{0, 0}) {
+		t.Errorf("Projected = %v, want {0, 0}", results[0].Projected)
+	}
+	if results[1].Projected != (orb.Point{0, 1}) {
+		t.Errorf("Projected = %v, want {0, 1}", results[1].Projected)
+	}
+}